@@ -0,0 +1,125 @@
+// Package code assigns every salesinvoice validation failure a stable,
+// numeric error code so that callers (HTTP handlers, i18n layers, other
+// services) can switch on a code instead of matching error strings.
+package code
+
+import "fmt"
+
+// Scope identifies which subsystem raised the error.
+type Scope int
+
+const (
+	ScopeSalesInvoice Scope = iota + 1
+	ScopePOS
+	ScopeInterCompany
+)
+
+func (s Scope) String() string {
+	switch s {
+	case ScopeSalesInvoice:
+		return "SalesInvoice"
+	case ScopePOS:
+		return "POS"
+	case ScopeInterCompany:
+		return "InterCompany"
+	default:
+		return "Unknown"
+	}
+}
+
+// Category buckets errors by failure kind. Values are spaced by 100 so
+// new categories can be inserted between existing ones without a
+// renumbering.
+type Category int
+
+const (
+	CatInput    Category = 100
+	CatDB       Category = 200
+	CatResource Category = 300
+	CatAuth     Category = 500
+	CatSystem   Category = 600
+	CatPubSub   Category = 700
+)
+
+func (c Category) String() string {
+	switch c {
+	case CatInput:
+		return "Input"
+	case CatDB:
+		return "DB"
+	case CatResource:
+		return "Resource"
+	case CatAuth:
+		return "Auth"
+	case CatSystem:
+		return "System"
+	case CatPubSub:
+		return "PubSub"
+	default:
+		return "Unknown"
+	}
+}
+
+// Code is the composite, API-stable error code: scope*1_000_000 +
+// category*100 + detail.
+type Code int
+
+// New composes a Code from its parts. detail is an offset within the
+// category, scoped by convention to a single feature area.
+func New(scope Scope, category Category, detail int) Code {
+	return Code(int(scope)*1_000_000 + int(category)*100 + detail)
+}
+
+// Scope returns the Scope encoded in c.
+func (c Code) Scope() Scope {
+	return Scope(int(c) / 1_000_000)
+}
+
+// Category returns the Category encoded in c.
+func (c Code) Category() Category {
+	return Category((int(c) / 100) % 10_000)
+}
+
+// Error is a registry-backed sentinel carrying a stable Code and its
+// default human-readable message.
+type Error struct {
+	code    Code
+	message string
+}
+
+func (e *Error) Error() string { return e.message }
+
+// Code returns the stable code identifying this sentinel.
+func (e *Error) Code() Code { return e.code }
+
+// Registry maps Codes to the default message they were registered with.
+type Registry map[Code]*Error
+
+// DefaultRegistry is the process-wide registry consulted by Lookup.
+var DefaultRegistry = Registry{}
+
+// Register creates the sentinel Error for scope/category/detail and
+// records it in r. Registering the same code twice with a different
+// message panics: that would mean two unrelated sentinels silently
+// collided on one code, which defeats the point of having stable codes.
+func (r Registry) Register(scope Scope, category Category, detail int, message string) *Error {
+	c := New(scope, category, detail)
+	if existing, ok := r[c]; ok {
+		if existing.message != message {
+			panic(fmt.Sprintf("code: %d already registered with a different message (%q vs %q)", c, existing.message, message))
+		}
+		return existing
+	}
+	e := &Error{code: c, message: message}
+	r[c] = e
+	return e
+}
+
+// Lookup returns the default message registered for c, if any.
+func (r Registry) Lookup(c Code) (string, bool) {
+	e, ok := r[c]
+	if !ok {
+		return "", false
+	}
+	return e.message, true
+}
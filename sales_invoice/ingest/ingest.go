@@ -0,0 +1,134 @@
+// Package ingest upserts incoming Sales Invoices and publishes lifecycle
+// events, modelled on the payments ingester pattern: load the prior
+// version, diff a whitelisted set of fields, and only publish an event
+// when something an external consumer would care about actually changed.
+package ingest
+
+import (
+	"context"
+	"fmt"
+
+	salesinvoice "github.com/Aagambot/AI-modernization-tool/sales_invoice"
+)
+
+// Store abstracts wherever the previously-ingested invoice is persisted.
+type Store interface {
+	Load(invoiceName string) (inv salesinvoice.SalesInvoice, found bool, err error)
+	Save(inv salesinvoice.SalesInvoice) error
+}
+
+// Ingester upserts invoices against a Store and reports changes to an
+// InvoiceEventPublisher.
+type Ingester struct {
+	Store     Store
+	Publisher salesinvoice.InvoiceEventPublisher
+}
+
+// NewIngester wires a Store and InvoiceEventPublisher into an Ingester.
+func NewIngester(store Store, publisher salesinvoice.InvoiceEventPublisher) *Ingester {
+	return &Ingester{Store: store, Publisher: publisher}
+}
+
+// UpsertInvoice stores incoming and publishes the appropriate event: a
+// created event for an invoice not previously seen, or an updated event
+// carrying changed_fields when at least one whitelisted field differs
+// from the stored version. Reprocessing an identical invoice saves it
+// again (so timestamps/non-whitelisted fields stay current) but publishes
+// nothing.
+func (g *Ingester) UpsertInvoice(ctx context.Context, incoming salesinvoice.SalesInvoice) (updated bool, err error) {
+	prior, found, err := g.Store.Load(incoming.Name)
+	if err != nil {
+		return false, fmt.Errorf("ingest: load %q: %w", incoming.Name, err)
+	}
+
+	if !found {
+		if err := g.Store.Save(incoming); err != nil {
+			return false, fmt.Errorf("ingest: save %q: %w", incoming.Name, err)
+		}
+		if err := g.Publisher.Publish(salesinvoice.InvoiceEvent{
+			Type:        salesinvoice.EventInvoiceCreated,
+			InvoiceName: incoming.Name,
+		}); err != nil {
+			return false, fmt.Errorf("ingest: publish created event for %q: %w", incoming.Name, err)
+		}
+		return true, nil
+	}
+
+	changed := changedFields(prior, incoming)
+	if err := g.Store.Save(incoming); err != nil {
+		return false, fmt.Errorf("ingest: save %q: %w", incoming.Name, err)
+	}
+	if len(changed) == 0 {
+		return false, nil
+	}
+
+	if err := g.Publisher.Publish(salesinvoice.InvoiceEvent{
+		Type:          salesinvoice.EventInvoiceUpdated,
+		InvoiceName:   incoming.Name,
+		ChangedFields: changed,
+	}); err != nil {
+		return false, fmt.Errorf("ingest: publish updated event for %q: %w", incoming.Name, err)
+	}
+	return true, nil
+}
+
+// changedFields returns the whitelisted field names that differ between
+// prior and incoming, in a stable order.
+func changedFields(prior, incoming salesinvoice.SalesInvoice) []string {
+	var changed []string
+	if prior.Customer != incoming.Customer {
+		changed = append(changed, "customer")
+	}
+	if prior.Company != incoming.Company {
+		changed = append(changed, "company")
+	}
+	if prior.GrandTotal != incoming.GrandTotal {
+		changed = append(changed, "grand_total")
+	}
+	if prior.RoundedTotal != incoming.RoundedTotal {
+		changed = append(changed, "rounded_total")
+	}
+	if prior.PaidAmount != incoming.PaidAmount {
+		changed = append(changed, "paid_amount")
+	}
+	if prior.WriteOffAmount != incoming.WriteOffAmount {
+		changed = append(changed, "write_off_amount")
+	}
+	if !sameItems(prior.Items, incoming.Items) {
+		changed = append(changed, "items")
+	}
+	if !samePayments(prior.Payments, incoming.Payments) {
+		changed = append(changed, "payments")
+	}
+	return changed
+}
+
+// sameItems compares item lines by ItemCode+Qty+SalesOrder+DeliveryNote,
+// so unrelated fields (e.g. DeliveredBySupplier) don't trigger events.
+func sameItems(a, b []salesinvoice.SalesInvoiceItem) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if itemKey(a[i]) != itemKey(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func itemKey(i salesinvoice.SalesInvoiceItem) string {
+	return fmt.Sprintf("%s|%v|%s|%s", i.ItemCode, i.Qty, i.SalesOrder, i.DeliveryNote)
+}
+
+func samePayments(a, b []salesinvoice.SalesInvoicePayment) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].ModeOfPayment != b[i].ModeOfPayment || a[i].Amount != b[i].Amount {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,174 @@
+package ingest
+
+import (
+	"context"
+	"testing"
+
+	salesinvoice "github.com/Aagambot/AI-modernization-tool/sales_invoice"
+)
+
+func TestUpsertInvoice_NewInvoiceEmitsCreated(t *testing.T) {
+	store := NewMemoryStore()
+	publisher := &MockPublisher{}
+	ingester := NewIngester(store, publisher)
+
+	inv := salesinvoice.SalesInvoice{Name: "SINV-0001", Customer: "Acme", GrandTotal: 100}
+
+	updated, err := ingester.UpsertInvoice(context.Background(), inv)
+	if err != nil {
+		t.Fatalf("UpsertInvoice() error = %v", err)
+	}
+	if !updated {
+		t.Error("expected updated=true for a new invoice")
+	}
+	if len(publisher.Events) != 1 || publisher.Events[0].Type != salesinvoice.EventInvoiceCreated {
+		t.Fatalf("events = %v, want one %s event", publisher.Events, salesinvoice.EventInvoiceCreated)
+	}
+}
+
+func TestUpsertInvoice_IdenticalReprocessEmitsNothing(t *testing.T) {
+	store := NewMemoryStore()
+	publisher := &MockPublisher{}
+	ingester := NewIngester(store, publisher)
+
+	inv := salesinvoice.SalesInvoice{
+		Name: "SINV-0002", Customer: "Acme", GrandTotal: 100,
+		Items: []salesinvoice.SalesInvoiceItem{{ItemCode: "WIDGET", Qty: 2}},
+	}
+
+	if _, err := ingester.UpsertInvoice(context.Background(), inv); err != nil {
+		t.Fatalf("first UpsertInvoice() error = %v", err)
+	}
+	publisher.Events = nil // only care about the reprocess below
+
+	updated, err := ingester.UpsertInvoice(context.Background(), inv)
+	if err != nil {
+		t.Fatalf("second UpsertInvoice() error = %v", err)
+	}
+	if updated {
+		t.Error("expected updated=false when reprocessing an identical invoice")
+	}
+	if len(publisher.Events) != 0 {
+		t.Errorf("expected zero events on identical reprocess, got %v", publisher.Events)
+	}
+}
+
+func TestUpsertInvoice_FieldChangeEmitsOneEventWithChangedFields(t *testing.T) {
+	store := NewMemoryStore()
+	publisher := &MockPublisher{}
+	ingester := NewIngester(store, publisher)
+
+	original := salesinvoice.SalesInvoice{Name: "SINV-0003", Customer: "Acme", GrandTotal: 100}
+	if _, err := ingester.UpsertInvoice(context.Background(), original); err != nil {
+		t.Fatalf("first UpsertInvoice() error = %v", err)
+	}
+	publisher.Events = nil
+
+	revised := original
+	revised.GrandTotal = 150
+
+	updated, err := ingester.UpsertInvoice(context.Background(), revised)
+	if err != nil {
+		t.Fatalf("second UpsertInvoice() error = %v", err)
+	}
+	if !updated {
+		t.Error("expected updated=true when grand_total changes")
+	}
+	if len(publisher.Events) != 1 {
+		t.Fatalf("expected exactly one event, got %d", len(publisher.Events))
+	}
+	event := publisher.Events[0]
+	if event.Type != salesinvoice.EventInvoiceUpdated {
+		t.Errorf("event.Type = %q, want %q", event.Type, salesinvoice.EventInvoiceUpdated)
+	}
+	if len(event.ChangedFields) != 1 || event.ChangedFields[0] != "grand_total" {
+		t.Errorf("event.ChangedFields = %v, want [grand_total]", event.ChangedFields)
+	}
+}
+
+func TestUpsertInvoice_ItemChanges(t *testing.T) {
+	tests := []struct {
+		name     string
+		before   []salesinvoice.SalesInvoiceItem
+		after    []salesinvoice.SalesInvoiceItem
+		wantDiff bool
+	}{
+		{
+			name:     "item added",
+			before:   []salesinvoice.SalesInvoiceItem{{ItemCode: "A", Qty: 1}},
+			after:    []salesinvoice.SalesInvoiceItem{{ItemCode: "A", Qty: 1}, {ItemCode: "B", Qty: 1}},
+			wantDiff: true,
+		},
+		{
+			name:     "item removed",
+			before:   []salesinvoice.SalesInvoiceItem{{ItemCode: "A", Qty: 1}, {ItemCode: "B", Qty: 1}},
+			after:    []salesinvoice.SalesInvoiceItem{{ItemCode: "A", Qty: 1}},
+			wantDiff: true,
+		},
+		{
+			name:     "quantity changed",
+			before:   []salesinvoice.SalesInvoiceItem{{ItemCode: "A", Qty: 1}},
+			after:    []salesinvoice.SalesInvoiceItem{{ItemCode: "A", Qty: 2}},
+			wantDiff: true,
+		},
+		{
+			name:     "unchanged",
+			before:   []salesinvoice.SalesInvoiceItem{{ItemCode: "A", Qty: 1, DeliveredBySupplier: false}},
+			after:    []salesinvoice.SalesInvoiceItem{{ItemCode: "A", Qty: 1, DeliveredBySupplier: true}},
+			wantDiff: false, // DeliveredBySupplier isn't in the whitelist
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewMemoryStore()
+			publisher := &MockPublisher{}
+			ingester := NewIngester(store, publisher)
+
+			base := salesinvoice.SalesInvoice{Name: "SINV-ITEMS", Items: tt.before}
+			if _, err := ingester.UpsertInvoice(context.Background(), base); err != nil {
+				t.Fatalf("seed UpsertInvoice() error = %v", err)
+			}
+			publisher.Events = nil
+
+			revised := base
+			revised.Items = tt.after
+			updated, err := ingester.UpsertInvoice(context.Background(), revised)
+			if err != nil {
+				t.Fatalf("UpsertInvoice() error = %v", err)
+			}
+			if updated != tt.wantDiff {
+				t.Errorf("updated = %v, want %v", updated, tt.wantDiff)
+			}
+		})
+	}
+}
+
+func TestUpsertInvoice_PaymentListChange(t *testing.T) {
+	store := NewMemoryStore()
+	publisher := &MockPublisher{}
+	ingester := NewIngester(store, publisher)
+
+	base := salesinvoice.SalesInvoice{
+		Name:     "SINV-0004",
+		Payments: []salesinvoice.SalesInvoicePayment{{ModeOfPayment: "Cash", Amount: 100}},
+	}
+	if _, err := ingester.UpsertInvoice(context.Background(), base); err != nil {
+		t.Fatalf("seed UpsertInvoice() error = %v", err)
+	}
+	publisher.Events = nil
+
+	revised := base
+	revised.Payments = append(revised.Payments, salesinvoice.SalesInvoicePayment{ModeOfPayment: "Card", Amount: 50})
+
+	updated, err := ingester.UpsertInvoice(context.Background(), revised)
+	if err != nil {
+		t.Fatalf("UpsertInvoice() error = %v", err)
+	}
+	if !updated {
+		t.Error("expected updated=true when the payment list changes")
+	}
+	if len(publisher.Events) != 1 || publisher.Events[0].ChangedFields[0] != "payments" {
+		t.Errorf("events = %v, want one event with changed_fields=[payments]", publisher.Events)
+	}
+}
@@ -0,0 +1,35 @@
+package ingest
+
+import salesinvoice "github.com/Aagambot/AI-modernization-tool/sales_invoice"
+
+// MemoryStore is an in-memory Store for tests and local runs.
+type MemoryStore struct {
+	invoices map[string]salesinvoice.SalesInvoice
+}
+
+// NewMemoryStore builds an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{invoices: make(map[string]salesinvoice.SalesInvoice)}
+}
+
+func (m *MemoryStore) Load(invoiceName string) (salesinvoice.SalesInvoice, bool, error) {
+	inv, found := m.invoices[invoiceName]
+	return inv, found, nil
+}
+
+func (m *MemoryStore) Save(inv salesinvoice.SalesInvoice) error {
+	m.invoices[inv.Name] = inv
+	return nil
+}
+
+// MockPublisher records every event published to it, for assertions in
+// tests.
+type MockPublisher struct {
+	Events []salesinvoice.InvoiceEvent
+}
+
+// Publish appends event to Events.
+func (m *MockPublisher) Publish(event salesinvoice.InvoiceEvent) error {
+	m.Events = append(m.Events, event)
+	return nil
+}
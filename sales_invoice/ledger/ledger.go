@@ -0,0 +1,386 @@
+// Package ledger posts Sales Invoice submissions as balanced double-entry
+// transactions, modelled on the lndhub.go ledger design (a small append-only
+// set of debit/credit pairs rather than a mutable running balance).
+package ledger
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	salesinvoice "github.com/Aagambot/AI-modernization-tool/sales_invoice"
+	"github.com/Aagambot/AI-modernization-tool/sales_invoice/postingdsl"
+)
+
+// --- 1. Domain Types ---
+
+// EntryType classifies a TransactionEntry the way lndhub.go tags its
+// ledger rows, so reversals can be told apart from forward postings.
+type EntryType int
+
+const (
+	Incoming EntryType = iota
+	Outgoing
+	Fee
+	FeeReserve
+	FeeReserveReversal
+	OutgoingReversal
+	WriteOff
+	WriteOffReversal
+)
+
+func (t EntryType) String() string {
+	switch t {
+	case Incoming:
+		return "Incoming"
+	case Outgoing:
+		return "Outgoing"
+	case Fee:
+		return "Fee"
+	case FeeReserve:
+		return "FeeReserve"
+	case FeeReserveReversal:
+		return "FeeReserveReversal"
+	case OutgoingReversal:
+		return "OutgoingReversal"
+	case WriteOff:
+		return "WriteOff"
+	case WriteOffReversal:
+		return "WriteOffReversal"
+	default:
+		return "Unknown"
+	}
+}
+
+// TransactionEntry is a single leg-pair of a double-entry posting.
+// ID is not part of the spec handed down by the ledger design doc, but a
+// stable identifier is required so that reversals can point back at the
+// entry they reverse via ParentID.
+type TransactionEntry struct {
+	ID              string
+	UserID          string
+	InvoiceID       string
+	ParentID        string // Set on *_Reversal entries; empty on forward postings.
+	DebitAccountID  string
+	CreditAccountID string
+	Amount          float64
+	EntryType       EntryType
+	CreatedAt       time.Time
+}
+
+// --- 2. The Port ---
+
+// ErrDuplicateEntry is returned when a Post call collides with an existing
+// entry for the same (invoice, debit account, credit account, entry type)
+// but disagrees on amount. Identical retries are not an error: they are
+// the idempotent no-op the uniqueness invariant exists to guarantee.
+var ErrDuplicateEntry = errors.New("ledger: conflicting entry already posted for this invoice/account/type")
+
+// ErrMissingReturnAgainst is returned when a return invoice (IsReturn)
+// does not name the invoice it reverses. ReturnAgainst is how PostSubmit
+// finds the entries to reverse, so a return without it can't be posted
+// as a reversal; falling through to a forward posting would silently
+// record the return as a regular sale instead.
+var ErrMissingReturnAgainst = errors.New("ledger: return invoice is missing ReturnAgainst")
+
+// LedgerPoster is what the domain needs from the books of account.
+// Implementations must enforce UNIQUE(invoice_id, debit_account_id, credit_account_id,
+// entry_type) so that re-submitting the same invoice never double-posts.
+type LedgerPoster interface {
+	Post(entry TransactionEntry) error
+	EntriesForInvoice(invoiceID string) ([]TransactionEntry, error)
+}
+
+// --- 3. Account Constants ---
+
+const (
+	AccountDebtors     = "Debtors"
+	AccountSalesIncome = "Sales Income"
+	AccountWriteOff    = "Write Off"
+)
+
+// --- 4. Poster: turns a submitted invoice into ledger entries ---
+
+// Poster generates TransactionEntry records for a SalesInvoice and posts
+// them through a LedgerPoster. It lives outside the salesinvoice package
+// (rather than as a method on SalesInvoice) so that the domain model does
+// not need to import its own accounting port.
+type Poster struct {
+	Ledger  LedgerPoster
+	Finance salesinvoice.FinanceLookup
+}
+
+// NewPoster wires a LedgerPoster and FinanceLookup into a Poster.
+func NewPoster(poster LedgerPoster, finance salesinvoice.FinanceLookup) *Poster {
+	return &Poster{Ledger: poster, Finance: finance}
+}
+
+// PostSubmit posts the entries produced by submitting inv. A return
+// invoice that references the invoice it reverses (ReturnAgainst) does
+// not post new forward entries; it reverses the original invoice's
+// entries instead, per the IsReturn=true case in the ledger design.
+// ReturnAgainst is mandatory on a return: without it there is nothing to
+// reverse, so PostSubmit errors rather than falling through to a forward
+// posting that would record the return as an ordinary sale.
+func (p *Poster) PostSubmit(inv *salesinvoice.SalesInvoice, userID string, now time.Time) error {
+	if inv.IsReturn {
+		if inv.ReturnAgainst == "" {
+			return fmt.Errorf("%w: invoice=%s", ErrMissingReturnAgainst, inv.Name)
+		}
+		return p.Reverse(inv.ReturnAgainst, now)
+	}
+
+	if err := p.postGrandTotal(inv, userID, now); err != nil {
+		return err
+	}
+	if err := p.postTaxLines(inv, userID, now); err != nil {
+		return err
+	}
+	if err := p.postPayments(inv, userID, now); err != nil {
+		return err
+	}
+	return p.postWriteOff(inv, userID, now)
+}
+
+func (p *Poster) postGrandTotal(inv *salesinvoice.SalesInvoice, userID string, now time.Time) error {
+	if inv.GrandTotal == 0 {
+		return nil
+	}
+	return p.Ledger.Post(TransactionEntry{
+		ID:              entryID(inv.Name, AccountDebtors, AccountSalesIncome, Incoming),
+		UserID:          userID,
+		InvoiceID:       inv.Name,
+		DebitAccountID:  AccountDebtors,
+		CreditAccountID: AccountSalesIncome,
+		Amount:          inv.GrandTotal,
+		EntryType:       Incoming,
+		CreatedAt:       now,
+	})
+}
+
+// postTaxLines posts one consolidated entry per distinct tax account
+// rather than one per line item: two or more items can share the same
+// TaxCategory (e.g. several lines all at standard VAT), and the posting
+// key is (invoice, Debtors, tax account, Fee) with no item index, so
+// posting per-item would collide as an ErrDuplicateEntry on the second
+// item instead of summing into a single tax liability.
+func (p *Poster) postTaxLines(inv *salesinvoice.SalesInvoice, userID string, now time.Time) error {
+	amounts := map[string]float64{}
+	var order []string
+	for _, item := range inv.Items {
+		if item.TaxCategory == "" {
+			continue
+		}
+		template, err := p.Finance.GetTaxTemplate(item.TaxCategory)
+		if err != nil {
+			return fmt.Errorf("ledger: tax template for category %q: %w", item.TaxCategory, err)
+		}
+		if _, seen := amounts[template.ID]; !seen {
+			order = append(order, template.ID)
+		}
+		amounts[template.ID] += item.TaxableAmount * template.Rate
+	}
+
+	for _, taxAccount := range order {
+		amount := amounts[taxAccount]
+		if amount == 0 {
+			continue
+		}
+		if err := p.Ledger.Post(TransactionEntry{
+			ID:              entryID(inv.Name, AccountDebtors, taxAccount, Fee),
+			UserID:          userID,
+			InvoiceID:       inv.Name,
+			DebitAccountID:  AccountDebtors,
+			CreditAccountID: taxAccount,
+			Amount:          amount,
+			EntryType:       Fee,
+			CreatedAt:       now,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *Poster) postPayments(inv *salesinvoice.SalesInvoice, userID string, now time.Time) error {
+	for _, payment := range inv.Payments {
+		if payment.Amount == 0 {
+			continue
+		}
+		if err := p.Ledger.Post(TransactionEntry{
+			ID:              entryID(inv.Name, payment.ModeOfPayment, AccountDebtors, Incoming),
+			UserID:          userID,
+			InvoiceID:       inv.Name,
+			DebitAccountID:  payment.ModeOfPayment,
+			CreditAccountID: AccountDebtors,
+			Amount:          payment.Amount,
+			EntryType:       Incoming,
+			CreatedAt:       now,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeOffCustomerAddress is the postingdsl address the default program's
+// write-off leg resolves its (bounded) customer source to. postWriteOff
+// maps it back onto AccountDebtors before posting, since the rest of
+// Poster's entries (and debtorsBalance's read of them) are all keyed on
+// that one constant, not on a per-customer address.
+func writeOffCustomerAddress(customer string) string {
+	return string(postingdsl.Resolve("@customer:%customer%", map[string]string{"customer": customer}))
+}
+
+// postWriteOff posts inv.WriteOffAmount through the postingdsl default
+// program instead of a hand-written entry, so the write-off leg gets the
+// same bounded-source guarantee (can't write off more than is still owed
+// on this invoice) that the DSL compiler derives for it. Every other leg
+// (grand_total/tax/payment) is left to postGrandTotal/postTaxLines/
+// postPayments above, since they post per line item or per payment mode
+// and the default program only models one of each.
+//
+// The DSL resolves its own addresses (@customer:..., @write_off:world),
+// which are meaningless to the rest of the ledger: postGrandTotal,
+// postTaxLines and postPayments all read and write AccountDebtors, and
+// debtorsBalance sums entries keyed on it too. So before posting, the
+// resolved customer address is translated back to AccountDebtors (and
+// @write_off:world to AccountWriteOff) rather than posted verbatim -
+// otherwise the write-off would land in an account nothing else reads,
+// leaving the invoice's real Debtors balance untouched.
+func (p *Poster) postWriteOff(inv *salesinvoice.SalesInvoice, userID string, now time.Time) error {
+	if inv.WriteOffAmount == 0 {
+		return nil
+	}
+
+	prog, err := postingdsl.CompileDefault()
+	if err != nil {
+		return fmt.Errorf("ledger: compile default posting program: %w", err)
+	}
+
+	exec := postingdsl.NewExecutor(&debtorsBalance{ledger: p.Ledger, invoiceID: inv.Name})
+	entries, err := exec.Execute(prog,
+		map[string]string{"customer": inv.Customer},
+		map[string]float64{"write_off_amount": inv.WriteOffAmount},
+	)
+	if err != nil {
+		return fmt.Errorf("ledger: write-off posting for %s: %w", inv.Name, err)
+	}
+
+	customerAddr := writeOffCustomerAddress(inv.Customer)
+	toLedgerAccount := func(addr string) string {
+		switch addr {
+		case customerAddr:
+			return AccountDebtors
+		case "@write_off:world":
+			return AccountWriteOff
+		default:
+			return addr
+		}
+	}
+
+	for _, e := range entries {
+		debit := toLedgerAccount(e.DebitAccountID)
+		credit := toLedgerAccount(e.CreditAccountID)
+		if err := p.Ledger.Post(TransactionEntry{
+			ID:              entryID(inv.Name, debit, credit, WriteOff),
+			UserID:          userID,
+			InvoiceID:       inv.Name,
+			DebitAccountID:  debit,
+			CreditAccountID: credit,
+			Amount:          e.Amount,
+			EntryType:       WriteOff,
+			CreatedAt:       now,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// debtorsBalance adapts a LedgerPoster into the postingdsl.BalanceProvider
+// the write-off leg's bounded customer source needs: how much of this
+// invoice's Debtors debt is still outstanding, net of whatever has
+// already been posted against it (the grand total/tax debits, and any
+// payment credits). It ignores the requested address and currency since
+// a Poster only ever tracks one customer's debt per invoice.
+type debtorsBalance struct {
+	ledger    LedgerPoster
+	invoiceID string
+}
+
+func (b *debtorsBalance) GetBalance(postingdsl.Address, string) (float64, error) {
+	entries, err := b.ledger.EntriesForInvoice(b.invoiceID)
+	if err != nil {
+		return 0, err
+	}
+	var balance float64
+	for _, e := range entries {
+		switch {
+		case e.DebitAccountID == AccountDebtors:
+			balance += e.Amount
+		case e.CreditAccountID == AccountDebtors:
+			balance -= e.Amount
+		}
+	}
+	return balance, nil
+}
+
+// --- 5. Reversal ---
+
+// reversalTypeFor reports the EntryType a reversal of entryType should
+// carry. Outgoing, FeeReserve and WriteOff each have a dedicated
+// *_Reversal counterpart, since they represent money or debt actually
+// moving rather than a sales posting being mirrored. Incoming and Fee
+// reverse under their own type with the accounts swapped. Anything else
+// (the *_Reversal types themselves) is left untouched by Reverse.
+func reversalTypeFor(t EntryType) (EntryType, bool) {
+	switch t {
+	case Outgoing:
+		return OutgoingReversal, true
+	case FeeReserve:
+		return FeeReserveReversal, true
+	case WriteOff:
+		return WriteOffReversal, true
+	case Incoming, Fee:
+		// Forward sales postings reverse by swapping accounts under the
+		// same forward type; there is no *_Reversal counterpart for them.
+		return t, true
+	default:
+		return t, false
+	}
+}
+
+// Reverse emits *_Reversal (or account-swapped) entries for every entry
+// already posted against invoiceName, linked back to the entries they
+// reverse via ParentID. It never deletes or mutates existing rows.
+func (p *Poster) Reverse(invoiceName string, now time.Time) error {
+	entries, err := p.Ledger.EntriesForInvoice(invoiceName)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		reversalType, ok := reversalTypeFor(e.EntryType)
+		if !ok {
+			continue
+		}
+		if err := p.Ledger.Post(TransactionEntry{
+			ID:              entryID(invoiceName, e.CreditAccountID, e.DebitAccountID, reversalType) + "-rev-" + e.ID,
+			UserID:          e.UserID,
+			InvoiceID:       e.InvoiceID,
+			ParentID:        e.ID,
+			DebitAccountID:  e.CreditAccountID,
+			CreditAccountID: e.DebitAccountID,
+			Amount:          e.Amount,
+			EntryType:       reversalType,
+			CreatedAt:       now,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func entryID(invoiceID, debitAccountID, creditAccountID string, entryType EntryType) string {
+	return fmt.Sprintf("%s|%s|%s|%s", invoiceID, debitAccountID, creditAccountID, entryType)
+}
@@ -0,0 +1,318 @@
+package ledger
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	salesinvoice "github.com/Aagambot/AI-modernization-tool/sales_invoice"
+	"github.com/Aagambot/AI-modernization-tool/sales_invoice/postingdsl"
+)
+
+type stubFinance struct {
+	templates map[string]salesinvoice.TaxTemplate
+}
+
+func (s *stubFinance) GetExchangeRate(string, string) (float64, error) { return 1, nil }
+func (s *stubFinance) VerifyAccount(string, string) (bool, error)      { return true, nil }
+func (s *stubFinance) GetTaxTemplate(category string) (salesinvoice.TaxTemplate, error) {
+	return s.templates[category], nil
+}
+func (s *stubFinance) GetAllowedTaxCodes(company, jurisdiction string) ([]string, []string, error) {
+	return nil, nil, nil
+}
+
+func balanced(t *testing.T, entries []TransactionEntry) {
+	t.Helper()
+	var debits, credits float64
+	for _, e := range entries {
+		if e.DebitAccountID != "" {
+			debits += e.Amount
+		}
+		if e.CreditAccountID != "" {
+			credits += e.Amount
+		}
+	}
+	// Every entry is one debit leg and one credit leg of equal amount, so
+	// the books balance as long as both legs name an account; a leg is only
+	// counted when it names one, so a one-sided entry (an empty debit or
+	// credit account) puts the totals out of balance.
+	if debits != credits {
+		t.Errorf("ledger out of balance: debits=%.2f credits=%.2f", debits, credits)
+	}
+}
+
+func TestPostSubmit_SimpleInvoice(t *testing.T) {
+	mem := NewMemoryLedger()
+	finance := &stubFinance{}
+	poster := NewPoster(mem, finance)
+
+	inv := &salesinvoice.SalesInvoice{
+		Name:       "SINV-0001",
+		GrandTotal: 118,
+		Payments: []salesinvoice.SalesInvoicePayment{
+			{ModeOfPayment: "Cash", Amount: 118},
+		},
+	}
+
+	if err := poster.PostSubmit(inv, "user-1", time.Unix(0, 0)); err != nil {
+		t.Fatalf("PostSubmit() error = %v", err)
+	}
+
+	entries, err := mem.EntriesForInvoice(inv.Name)
+	if err != nil {
+		t.Fatalf("EntriesForInvoice() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries (grand total + payment), got %d", len(entries))
+	}
+	balanced(t, entries)
+}
+
+func TestPostSubmit_WithTaxLines(t *testing.T) {
+	mem := NewMemoryLedger()
+	finance := &stubFinance{templates: map[string]salesinvoice.TaxTemplate{
+		"VAT": {ID: "VAT Payable", Rate: 0.18},
+	}}
+	poster := NewPoster(mem, finance)
+
+	inv := &salesinvoice.SalesInvoice{
+		Name:       "SINV-0002",
+		GrandTotal: 118,
+		Items: []salesinvoice.SalesInvoiceItem{
+			{ItemCode: "WIDGET", TaxCategory: "VAT", TaxableAmount: 100},
+		},
+	}
+
+	if err := poster.PostSubmit(inv, "user-1", time.Unix(0, 0)); err != nil {
+		t.Fatalf("PostSubmit() error = %v", err)
+	}
+
+	entries, _ := mem.EntriesForInvoice(inv.Name)
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries (grand total + tax), got %d", len(entries))
+	}
+	balanced(t, entries)
+}
+
+func TestPostSubmit_MultipleItemsSameTaxCategory(t *testing.T) {
+	mem := NewMemoryLedger()
+	finance := &stubFinance{templates: map[string]salesinvoice.TaxTemplate{
+		"VAT": {ID: "VAT Payable", Rate: 0.18},
+	}}
+	poster := NewPoster(mem, finance)
+
+	inv := &salesinvoice.SalesInvoice{
+		Name:       "SINV-0006",
+		GrandTotal: 236,
+		Items: []salesinvoice.SalesInvoiceItem{
+			{ItemCode: "WIDGET", TaxCategory: "VAT", TaxableAmount: 100},
+			{ItemCode: "GADGET", TaxCategory: "VAT", TaxableAmount: 100},
+		},
+	}
+
+	if err := poster.PostSubmit(inv, "user-1", time.Unix(0, 0)); err != nil {
+		t.Fatalf("PostSubmit() error = %v", err)
+	}
+
+	entries, _ := mem.EntriesForInvoice(inv.Name)
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries (grand total + one consolidated tax entry), got %d", len(entries))
+	}
+	var taxEntry TransactionEntry
+	for _, e := range entries {
+		if e.CreditAccountID == "VAT Payable" {
+			taxEntry = e
+		}
+	}
+	if taxEntry.Amount != 36 {
+		t.Errorf("expected consolidated VAT entry of 36 (2 x 100 x 0.18), got %.2f", taxEntry.Amount)
+	}
+	balanced(t, entries)
+}
+
+func TestPostSubmit_Idempotent(t *testing.T) {
+	mem := NewMemoryLedger()
+	poster := NewPoster(mem, &stubFinance{})
+
+	inv := &salesinvoice.SalesInvoice{Name: "SINV-0003", GrandTotal: 50}
+	now := time.Unix(0, 0)
+
+	if err := poster.PostSubmit(inv, "user-1", now); err != nil {
+		t.Fatalf("first PostSubmit() error = %v", err)
+	}
+	if err := poster.PostSubmit(inv, "user-1", now); err != nil {
+		t.Fatalf("retry PostSubmit() should be idempotent, got error = %v", err)
+	}
+
+	entries, _ := mem.EntriesForInvoice(inv.Name)
+	if len(entries) != 1 {
+		t.Fatalf("expected retry to be a no-op, got %d entries", len(entries))
+	}
+}
+
+func TestPostSubmit_DuplicateConflict(t *testing.T) {
+	mem := NewMemoryLedger()
+	now := time.Unix(0, 0)
+
+	if err := mem.Post(TransactionEntry{
+		InvoiceID: "SINV-0004", DebitAccountID: AccountDebtors, CreditAccountID: AccountSalesIncome,
+		EntryType: Incoming, Amount: 100, CreatedAt: now,
+	}); err != nil {
+		t.Fatalf("initial Post() error = %v", err)
+	}
+
+	err := mem.Post(TransactionEntry{
+		InvoiceID: "SINV-0004", DebitAccountID: AccountDebtors, CreditAccountID: AccountSalesIncome,
+		EntryType: Incoming, Amount: 999, CreatedAt: now,
+	})
+	if err == nil {
+		t.Fatal("expected conflicting amount on the same key to error")
+	}
+}
+
+// debtorsBalanceFor reports the net Debtors balance posted against
+// invoiceID, the same way debtorsBalance.GetBalance does, so tests can
+// assert on the actual receivable rather than just debits==credits
+// (which holds for every entry regardless of which accounts it uses).
+func debtorsBalanceFor(t *testing.T, mem *MemoryLedger, invoiceID string) float64 {
+	t.Helper()
+	entries, err := mem.EntriesForInvoice(invoiceID)
+	if err != nil {
+		t.Fatalf("EntriesForInvoice() error = %v", err)
+	}
+	var balance float64
+	for _, e := range entries {
+		switch {
+		case e.DebitAccountID == AccountDebtors:
+			balance += e.Amount
+		case e.CreditAccountID == AccountDebtors:
+			balance -= e.Amount
+		}
+	}
+	return balance
+}
+
+func TestPostSubmit_WriteOff(t *testing.T) {
+	mem := NewMemoryLedger()
+	poster := NewPoster(mem, &stubFinance{})
+
+	inv := &salesinvoice.SalesInvoice{
+		Name:           "SINV-0008",
+		Customer:       "ACME",
+		GrandTotal:     100,
+		WriteOffAmount: 40,
+	}
+
+	if err := poster.PostSubmit(inv, "user-1", time.Unix(0, 0)); err != nil {
+		t.Fatalf("PostSubmit() error = %v", err)
+	}
+
+	entries, _ := mem.EntriesForInvoice(inv.Name)
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries (grand total + write-off), got %d", len(entries))
+	}
+	balanced(t, entries)
+
+	if got, want := debtorsBalanceFor(t, mem, inv.Name), 60.0; got != want {
+		t.Errorf("Debtors balance after write-off = %.2f, want %.2f", got, want)
+	}
+
+	var writeOffEntry TransactionEntry
+	for _, e := range entries {
+		if e.EntryType == WriteOff {
+			writeOffEntry = e
+		}
+	}
+	if writeOffEntry.DebitAccountID != AccountWriteOff || writeOffEntry.CreditAccountID != AccountDebtors {
+		t.Errorf("write-off entry accounts = debit %q credit %q, want debit %q credit %q",
+			writeOffEntry.DebitAccountID, writeOffEntry.CreditAccountID, AccountWriteOff, AccountDebtors)
+	}
+}
+
+func TestPostSubmit_WriteOffExceedingOutstandingDebtErrors(t *testing.T) {
+	mem := NewMemoryLedger()
+	poster := NewPoster(mem, &stubFinance{})
+
+	inv := &salesinvoice.SalesInvoice{
+		Name:           "SINV-0009",
+		Customer:       "ACME",
+		GrandTotal:     100,
+		WriteOffAmount: 150,
+	}
+
+	if err := poster.PostSubmit(inv, "user-1", time.Unix(0, 0)); !errors.Is(err, postingdsl.ErrInsufficientBalance) {
+		t.Fatalf("PostSubmit() error = %v, want ErrInsufficientBalance", err)
+	}
+}
+
+func TestPostSubmit_SecondWriteOffBoundedByRemainingDebt(t *testing.T) {
+	mem := NewMemoryLedger()
+	poster := NewPoster(mem, &stubFinance{})
+	now := time.Unix(0, 0)
+
+	inv := &salesinvoice.SalesInvoice{Name: "SINV-0010", Customer: "ACME", GrandTotal: 100, WriteOffAmount: 60}
+	if err := poster.PostSubmit(inv, "user-1", now); err != nil {
+		t.Fatalf("PostSubmit() error = %v", err)
+	}
+	if got, want := debtorsBalanceFor(t, mem, inv.Name), 40.0; got != want {
+		t.Fatalf("Debtors balance after first write-off = %.2f, want %.2f", got, want)
+	}
+
+	// A second submission writing off another 60 would take the total
+	// write-off to 120 against a 100 invoice; debtorsBalance must see the
+	// first write-off's effect on Debtors and reject it, not approve it
+	// against the original, pre-write-off balance of 100.
+	again := &salesinvoice.SalesInvoice{Name: "SINV-0010", Customer: "ACME", GrandTotal: 100, WriteOffAmount: 60}
+	err := poster.postWriteOff(again, "user-1", now)
+	if !errors.Is(err, postingdsl.ErrInsufficientBalance) {
+		t.Fatalf("second postWriteOff() error = %v, want ErrInsufficientBalance", err)
+	}
+}
+
+func TestPostSubmit_ReturnWithoutReturnAgainstErrors(t *testing.T) {
+	mem := NewMemoryLedger()
+	poster := NewPoster(mem, &stubFinance{})
+
+	creditNote := &salesinvoice.SalesInvoice{Name: "SINV-0007-CN", IsReturn: true, GrandTotal: 50}
+	err := poster.PostSubmit(creditNote, "user-1", time.Unix(0, 0))
+	if !errors.Is(err, ErrMissingReturnAgainst) {
+		t.Fatalf("PostSubmit() error = %v, want ErrMissingReturnAgainst", err)
+	}
+
+	entries, _ := mem.EntriesForInvoice(creditNote.Name)
+	if len(entries) != 0 {
+		t.Fatalf("expected no entries posted for a rejected return, got %d", len(entries))
+	}
+}
+
+func TestReverse_LinksToParentAndBalances(t *testing.T) {
+	mem := NewMemoryLedger()
+	poster := NewPoster(mem, &stubFinance{})
+	now := time.Unix(0, 0)
+
+	original := &salesinvoice.SalesInvoice{Name: "SINV-0005", GrandTotal: 200}
+	if err := poster.PostSubmit(original, "user-1", now); err != nil {
+		t.Fatalf("PostSubmit() error = %v", err)
+	}
+
+	creditNote := &salesinvoice.SalesInvoice{
+		Name: "SINV-0005-CN", IsReturn: true, ReturnAgainst: original.Name, GrandTotal: 200,
+	}
+	if err := poster.PostSubmit(creditNote, "user-1", now); err != nil {
+		t.Fatalf("PostSubmit() on return error = %v", err)
+	}
+
+	all := mem.All()
+	if len(all) != 2 {
+		t.Fatalf("expected original + reversal entry, got %d", len(all))
+	}
+	reversal := all[1]
+	if reversal.ParentID != all[0].ID {
+		t.Errorf("reversal.ParentID = %q, want %q", reversal.ParentID, all[0].ID)
+	}
+	if reversal.DebitAccountID != all[0].CreditAccountID || reversal.CreditAccountID != all[0].DebitAccountID {
+		t.Errorf("reversal accounts not swapped: %+v vs %+v", reversal, all[0])
+	}
+	balanced(t, all)
+}
@@ -0,0 +1,58 @@
+package ledger
+
+import "fmt"
+
+// MemoryLedger is an in-memory LedgerPoster for tests and local runs. It
+// enforces UNIQUE(invoice_id, debit_account_id, credit_account_id,
+// entry_type) itself so callers can exercise the same idempotency
+// guarantees a real accounting store would provide.
+type MemoryLedger struct {
+	entries []TransactionEntry
+	byKey   map[string]TransactionEntry
+}
+
+// NewMemoryLedger builds an empty MemoryLedger.
+func NewMemoryLedger() *MemoryLedger {
+	return &MemoryLedger{byKey: make(map[string]TransactionEntry)}
+}
+
+func (m *MemoryLedger) key(e TransactionEntry) string {
+	return entryID(e.InvoiceID, e.DebitAccountID, e.CreditAccountID, e.EntryType)
+}
+
+// Post appends entry, unless an identical entry was already posted under
+// the same uniqueness key, in which case it is a no-op (idempotent
+// retry). A key collision with a different amount is reported as
+// ErrDuplicateEntry.
+func (m *MemoryLedger) Post(entry TransactionEntry) error {
+	key := m.key(entry)
+	if existing, ok := m.byKey[key]; ok {
+		if existing.Amount == entry.Amount {
+			return nil
+		}
+		return fmt.Errorf("%w: invoice=%s debit=%s credit=%s type=%s", ErrDuplicateEntry,
+			entry.InvoiceID, entry.DebitAccountID, entry.CreditAccountID, entry.EntryType)
+	}
+	m.byKey[key] = entry
+	m.entries = append(m.entries, entry)
+	return nil
+}
+
+// EntriesForInvoice returns every entry posted against invoiceID, in
+// posting order.
+func (m *MemoryLedger) EntriesForInvoice(invoiceID string) ([]TransactionEntry, error) {
+	var out []TransactionEntry
+	for _, e := range m.entries {
+		if e.InvoiceID == invoiceID {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+// All returns every entry the ledger has posted, in posting order.
+func (m *MemoryLedger) All() []TransactionEntry {
+	out := make([]TransactionEntry, len(m.entries))
+	copy(out, m.entries)
+	return out
+}
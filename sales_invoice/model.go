@@ -29,6 +29,7 @@ type SalesInvoice struct {
     RepresentsCompany    string // For Inter-company logic
     InterCompanyRef     string // For Inter-company logic
     Payments             []SalesInvoicePayment // For Payment clearing logic
+    ReturnAgainst        string // Name of the original invoice this credit note reverses
 }
 
 // SalesInvoiceItem represents a row in the items child table.
@@ -38,7 +39,11 @@ type SalesInvoiceItem struct {
 	SalesOrder   string // Link to Sales Order
 	DeliveryNote string // Link to Delivery Note
 	DeliveredBySupplier bool // Add this for drop-ship check
+	TaxCategory   string  // Category key passed to FinanceLookup.GetTaxTemplate
+	TaxableAmount float64 // Line amount the tax rate is applied against
 
+	GTUCode          string   // Jurisdictional goods/services tax classification, e.g. Polish JPK GTU
+	SpecialProcedure []string // Jurisdictional special-procedure tags, e.g. Polish JPK SP
 }
 
 // PrecisionSettings defines the rounding precision for calculations.
@@ -83,6 +88,9 @@ type FinanceLookup interface {
     GetExchangeRate(fromCurrency string, toCurrency string) (float64, error)
     VerifyAccount(accountID string, company string) (bool, error)
     GetTaxTemplate(category string) (TaxTemplate, error)
+    // GetAllowedTaxCodes returns the GTU and special-procedure codes a
+    // company may use for a given jurisdiction.
+    GetAllowedTaxCodes(company string, jurisdiction string) (gtu []string, sp []string, err error)
 }
 
 type CreditChecker interface {
@@ -95,3 +103,27 @@ type SalesInvoicePayment struct {
     Amount        float64
 }
 
+// --- 4. Events ---
+
+// Event type constants published by the ingest layer when an invoice is
+// first stored, or when a re-submitted invoice has a whitelisted field
+// change relative to the version already on file.
+const (
+	EventInvoiceCreated = "sales_invoice.created"
+	EventInvoiceUpdated = "sales_invoice.updated"
+)
+
+// InvoiceEvent is published whenever an invoice is created or meaningfully
+// changed. ChangedFields is only populated for EventInvoiceUpdated.
+type InvoiceEvent struct {
+	Type          string
+	InvoiceName   string
+	ChangedFields []string
+}
+
+// InvoiceEventPublisher abstracts wherever invoice lifecycle events are
+// delivered (pub/sub, webhook, outbox table, ...).
+type InvoiceEventPublisher interface {
+	Publish(event InvoiceEvent) error
+}
+
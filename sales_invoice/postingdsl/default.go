@@ -0,0 +1,40 @@
+package postingdsl
+
+// DefaultProgramSource is equivalent to the implicit posting ledger.Poster
+// already performs (Debtors <-> Sales Income <-> Tax <-> Cash), expressed
+// as a DSL program. Income and tax are declared unbounded sources: like
+// Formance's "world" account, they represent the other side of a
+// transaction this ledger doesn't track a balance for, so they are
+// excluded from NeededBalances. The customer account is the bounded
+// source in both the payment leg (a payment can't draw down more than the
+// customer's outstanding Debtors balance) and the write-off leg (you
+// can't write off more than the customer is actually owed), so there
+// source=@customer and destination=@write_off:world, the reverse of the
+// grand-total/tax legs, to credit (reduce) Debtors instead of debiting it.
+const DefaultProgramSource = `send [USD %grand_total%] (
+  source = @income:sales
+  allowing unbounded overdraft
+  destination = @customer:%customer%
+)
+
+send [USD %tax_amount%] (
+  source = @tax:%tax_account%
+  allowing unbounded overdraft
+  destination = @customer:%customer%
+)
+
+send [USD %paid_amount%] (
+  source = @customer:%customer%
+  destination = @cash:%mode_of_payment%
+)
+
+send [USD %write_off_amount%] (
+  source = @customer:%customer%
+  destination = @write_off:world
+)
+`
+
+// CompileDefault compiles DefaultProgramSource.
+func CompileDefault() (*Program, error) {
+	return Compile(DefaultProgramSource)
+}
@@ -0,0 +1,95 @@
+package postingdsl
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInsufficientBalance is returned when a bounded source's pre-fetched
+// balance can't cover the amount a statement tries to debit from it.
+var ErrInsufficientBalance = errors.New("postingdsl: bounded source has insufficient balance")
+
+// BalanceProvider resolves the current balance of a bounded source
+// address in a given currency. Production wiring adapts this to whatever
+// FinanceLookup/StockProvider calls the real balance actually lives
+// behind; the runtime only needs the resolved number.
+type BalanceProvider interface {
+	GetBalance(addr Address, currency string) (float64, error)
+}
+
+// Entry is one resolved debit/credit leg Execute produces from a
+// Statement. It deliberately mirrors the shape of
+// ledger.TransactionEntry's account/amount fields rather than importing
+// the ledger package: postingdsl compiles and runs programs in the
+// abstract, and it's the caller (e.g. ledger.Poster) that knows how to
+// turn an Entry into a TransactionEntry with invoice/user/timestamp
+// bookkeeping filled in.
+type Entry struct {
+	DebitAccountID  string
+	CreditAccountID string
+	Amount          float64
+	Currency        string
+}
+
+// Executor runs a compiled Program, turning it into Entry records a
+// caller can post through a ledger.LedgerPoster.
+type Executor struct {
+	Balances BalanceProvider
+}
+
+// NewExecutor wires a BalanceProvider into an Executor.
+func NewExecutor(balances BalanceProvider) *Executor {
+	return &Executor{Balances: balances}
+}
+
+// Execute resolves every statement's addresses against vars and its
+// amount against amounts (keyed by Amount.Var), checking bounded-source
+// balances before debiting them, and returns the resulting Entry
+// records. A statement whose amount is zero or missing from amounts is
+// skipped, since DSL programs list every possible leg (tax, write-off,
+// ...) whether or not a given invoice uses it.
+//
+// A bounded source's balance is fetched once per Execute call and then
+// drawn down locally as statements debit it, so two legs of the same
+// program sharing a source (e.g. a payment and a write-off both drawing
+// on the customer) are checked against what's actually left rather than
+// each being checked independently against the pre-transaction balance.
+func (ex *Executor) Execute(prog *Program, vars map[string]string, amounts map[string]float64) ([]Entry, error) {
+	var entries []Entry
+	remaining := map[Address]float64{}
+
+	for _, inst := range prog.Instructions {
+		amount, ok := amounts[inst.Amount.Var]
+		if !ok || amount == 0 {
+			continue
+		}
+
+		source := Resolve(inst.Source, vars)
+		destination := Resolve(inst.Destination, vars)
+
+		if !inst.Unbounded {
+			balance, ok := remaining[source]
+			if !ok {
+				fetched, err := ex.Balances.GetBalance(source, inst.Amount.Currency)
+				if err != nil {
+					return nil, fmt.Errorf("postingdsl: balance for %s: %w", source, err)
+				}
+				balance = fetched
+			}
+			if balance < amount {
+				return nil, fmt.Errorf("%w: %s has %.2f %s, need %.2f",
+					ErrInsufficientBalance, source, balance, inst.Amount.Currency, amount)
+			}
+			remaining[source] = balance - amount
+		}
+
+		entries = append(entries, Entry{
+			DebitAccountID:  string(destination),
+			CreditAccountID: string(source),
+			Amount:          amount,
+			Currency:        inst.Amount.Currency,
+		})
+	}
+
+	return entries, nil
+}
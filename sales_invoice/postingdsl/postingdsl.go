@@ -0,0 +1,155 @@
+// Package postingdsl compiles a small Numscript-style DSL describing how
+// an invoice's amounts flow between ledger accounts into a Program that
+// can be executed against a ledger.LedgerPoster. The shape is:
+//
+//	send [USD %grand_total%] (
+//	  source = @customer:%customer%
+//	  allowing unbounded overdraft
+//	  destination = @income:sales
+//	)
+//
+// Reusing the Formance-ledger compiler idea: an account that appears as a
+// source without "allowing unbounded overdraft" is a bounded source, and
+// only bounded sources need their balance pre-fetched before the runtime
+// can safely debit them.
+package postingdsl
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Address identifies a ledger account, possibly still carrying unresolved
+// %var% placeholders until Resolve substitutes them at execution time.
+type Address string
+
+// Amount is the quantity moved by a send statement: a currency and the
+// %var% name it's bound to at execution time.
+type Amount struct {
+	Currency string
+	Var      string
+}
+
+// Statement is one compiled `send` block.
+type Statement struct {
+	Amount      Amount
+	Source      Address
+	Unbounded   bool // true if the block declared "allowing unbounded overdraft"
+	Destination Address
+}
+
+// Program is the compiled output of a posting DSL source: every send
+// statement in order, plus the bookkeeping the compiler derived about
+// which sources are bounded and which (source, currency) balances the
+// runtime must pre-fetch.
+type Program struct {
+	Instructions []Statement
+	// Sources lists every source address referenced, in first-seen order.
+	Sources []Address
+	// NeededBalances maps each bounded source to the currencies it moves
+	// money in, so a caller wiring up a BalanceProvider knows up front
+	// which accounts it will be asked for; an unbounded source never
+	// appears here since Executor never checks it.
+	NeededBalances map[Address]map[Address]struct{}
+}
+
+var (
+	sendHeaderRe = regexp.MustCompile(`^send\s*\[\s*([A-Za-z0-9_]+)\s+%([A-Za-z0-9_.]+)%\s*\]\s*\($`)
+	sourceRe     = regexp.MustCompile(`^source\s*=\s*(\S+)$`)
+	destRe       = regexp.MustCompile(`^destination\s*=\s*(\S+)$`)
+)
+
+const unboundedClause = "allowing unbounded overdraft"
+
+// Compile parses src and derives the bounded-source bookkeeping described
+// in the package doc.
+func Compile(src string) (*Program, error) {
+	stmts, err := parse(src)
+	if err != nil {
+		return nil, err
+	}
+
+	prog := &Program{
+		Instructions:   stmts,
+		NeededBalances: map[Address]map[Address]struct{}{},
+	}
+	seen := map[Address]bool{}
+	for _, st := range stmts {
+		if !seen[st.Source] {
+			seen[st.Source] = true
+			prog.Sources = append(prog.Sources, st.Source)
+		}
+		if st.Unbounded {
+			continue
+		}
+		currency := Address(st.Amount.Currency)
+		if prog.NeededBalances[st.Source] == nil {
+			prog.NeededBalances[st.Source] = map[Address]struct{}{}
+		}
+		prog.NeededBalances[st.Source][currency] = struct{}{}
+	}
+	return prog, nil
+}
+
+func parse(src string) ([]Statement, error) {
+	var stmts []Statement
+	var cur *Statement
+
+	for i, raw := range strings.Split(src, "\n") {
+		line := strings.TrimSpace(raw)
+		if line == "" {
+			continue
+		}
+		lineNo := i + 1
+
+		switch {
+		case cur == nil:
+			m := sendHeaderRe.FindStringSubmatch(line)
+			if m == nil {
+				return nil, fmt.Errorf("postingdsl: line %d: expected \"send [CUR %%var%%] (\", got %q", lineNo, line)
+			}
+			cur = &Statement{Amount: Amount{Currency: m[1], Var: m[2]}}
+
+		case line == ")":
+			if cur.Source == "" || cur.Destination == "" {
+				return nil, fmt.Errorf("postingdsl: line %d: send block missing source or destination", lineNo)
+			}
+			stmts = append(stmts, *cur)
+			cur = nil
+
+		case line == unboundedClause:
+			cur.Unbounded = true
+
+		default:
+			if m := sourceRe.FindStringSubmatch(line); m != nil {
+				cur.Source = Address(m[1])
+				continue
+			}
+			if m := destRe.FindStringSubmatch(line); m != nil {
+				cur.Destination = Address(m[1])
+				continue
+			}
+			return nil, fmt.Errorf("postingdsl: line %d: unrecognized clause %q", lineNo, line)
+		}
+	}
+
+	if cur != nil {
+		return nil, fmt.Errorf("postingdsl: unterminated send block")
+	}
+	return stmts, nil
+}
+
+// Resolve substitutes every %var% placeholder in addr with vars[var],
+// leaving any placeholder with no binding untouched.
+func Resolve(addr Address, vars map[string]string) Address {
+	return Address(addrVarRe.ReplaceAllStringFunc(string(addr), func(m string) string {
+		key := strings.Trim(m, "%")
+		if v, ok := vars[key]; ok {
+			return v
+		}
+		return m
+	}))
+}
+
+var addrVarRe = regexp.MustCompile(`%([A-Za-z0-9_.]+)%`)
@@ -0,0 +1,171 @@
+package postingdsl
+
+import (
+	"errors"
+	"testing"
+)
+
+const twoLegProgram = `send [USD %grand_total%] (
+  source = @income:sales
+  allowing unbounded overdraft
+  destination = @customer:%customer%
+)
+
+send [USD %write_off_amount%] (
+  source = @customer:%customer%
+  destination = @write_off:world
+)
+`
+
+func TestCompile_BoundedVsUnboundedSources(t *testing.T) {
+	prog, err := Compile(twoLegProgram)
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	if len(prog.Instructions) != 2 {
+		t.Fatalf("expected 2 instructions, got %d", len(prog.Instructions))
+	}
+
+	bounded := Address("@customer:%customer%")
+	unbounded := Address("@income:sales")
+
+	if _, ok := prog.NeededBalances[bounded]; !ok {
+		t.Errorf("expected %s in NeededBalances (no unbounded overdraft clause)", bounded)
+	}
+	if _, ok := prog.NeededBalances[unbounded]; ok {
+		t.Errorf("world-style unbounded source %s must be excluded from NeededBalances", unbounded)
+	}
+}
+
+func TestCompile_SyntaxErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+	}{
+		{"missing send keyword", "source = @a\ndestination = @b\n)"},
+		{"unterminated block", "send [USD %x%] (\nsource = @a\ndestination = @b\n"},
+		{"missing destination", "send [USD %x%] (\nsource = @a\n)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Compile(tt.src); err == nil {
+				t.Error("expected a compile error, got nil")
+			}
+		})
+	}
+}
+
+func TestCompileDefault_WriteOffExcludedFromBalances(t *testing.T) {
+	prog, err := CompileDefault()
+	if err != nil {
+		t.Fatalf("CompileDefault() error = %v", err)
+	}
+
+	for source := range prog.NeededBalances {
+		if source == "@write_off:world" || source == "@income:sales" || source == "@tax:%tax_account%" {
+			t.Errorf("unbounded source %s must not require a pre-fetched balance", source)
+		}
+	}
+	if _, ok := prog.NeededBalances["@customer:%customer%"]; !ok {
+		t.Error("the bounded customer source should require a pre-fetched balance")
+	}
+}
+
+type stubBalances struct {
+	balances map[Address]float64
+}
+
+func (s *stubBalances) GetBalance(addr Address, currency string) (float64, error) {
+	return s.balances[addr], nil
+}
+
+func TestExecutor_ChecksBoundedSourceBalance(t *testing.T) {
+	prog, err := Compile(twoLegProgram)
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+
+	vars := map[string]string{"customer": "ACME"}
+
+	t.Run("sufficient balance succeeds", func(t *testing.T) {
+		balances := &stubBalances{balances: map[Address]float64{"@customer:ACME": 500}}
+		exec := NewExecutor(balances)
+
+		entries, err := exec.Execute(prog, vars, map[string]float64{"write_off_amount": 100})
+		if err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+		if len(entries) != 1 {
+			t.Fatalf("expected 1 entry, got %d", len(entries))
+		}
+		if entries[0].CreditAccountID != "@customer:ACME" || entries[0].DebitAccountID != "@write_off:world" {
+			t.Errorf("unexpected entry accounts: %+v", entries[0])
+		}
+	})
+
+	t.Run("insufficient balance errors", func(t *testing.T) {
+		balances := &stubBalances{balances: map[Address]float64{"@customer:ACME": 10}}
+		exec := NewExecutor(balances)
+
+		_, err := exec.Execute(prog, vars, map[string]float64{"write_off_amount": 100})
+		if !errors.Is(err, ErrInsufficientBalance) {
+			t.Errorf("Execute() error = %v, want ErrInsufficientBalance", err)
+		}
+	})
+
+	t.Run("unbounded source skips the balance check", func(t *testing.T) {
+		balances := &stubBalances{} // empty: would fail if checked
+		exec := NewExecutor(balances)
+
+		entries, err := exec.Execute(prog, vars, map[string]float64{"grand_total": 100})
+		if err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+		if len(entries) != 1 || entries[0].CreditAccountID != "@income:sales" {
+			t.Errorf("unexpected entries: %+v", entries)
+		}
+	})
+
+	t.Run("running balance is drawn down across statements sharing a source", func(t *testing.T) {
+		balances := &stubBalances{balances: map[Address]float64{"@customer:ACME": 150}}
+		exec := NewExecutor(balances)
+
+		shared := `send [USD %a%] (
+  source = @customer:%customer%
+  destination = @cash:Cash
+)
+
+send [USD %b%] (
+  source = @customer:%customer%
+  destination = @write_off:world
+)
+`
+		sharedProg, err := Compile(shared)
+		if err != nil {
+			t.Fatalf("Compile() error = %v", err)
+		}
+
+		_, err = exec.Execute(sharedProg, vars, map[string]float64{"a": 80, "b": 80})
+		if !errors.Is(err, ErrInsufficientBalance) {
+			t.Errorf("Execute() error = %v, want ErrInsufficientBalance once the first leg draws the shared balance down", err)
+		}
+	})
+}
+
+func TestExecutor_SkipsUnboundAmounts(t *testing.T) {
+	prog, err := CompileDefault()
+	if err != nil {
+		t.Fatalf("CompileDefault() error = %v", err)
+	}
+	exec := NewExecutor(&stubBalances{balances: map[Address]float64{"@customer:ACME": 1000}})
+
+	entries, err := exec.Execute(prog, map[string]string{"customer": "ACME"}, map[string]float64{
+		"grand_total": 118,
+	})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected only the grand_total leg to post, got %d entries: %+v", len(entries), entries)
+	}
+}
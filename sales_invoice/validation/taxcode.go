@@ -0,0 +1,89 @@
+package validation
+
+import (
+	salesinvoice "github.com/Aagambot/AI-modernization-tool/sales_invoice"
+	"github.com/Aagambot/AI-modernization-tool/sales_invoice/code"
+)
+
+// Tax-code errors, in the spirit of Polish JPK GTU/SP classification: an
+// item's GTU code or special-procedure tags must come from the
+// jurisdiction's allow-list, must not repeat within an item, and must not
+// combine procedures declared mutually exclusive.
+var (
+	ErrTaxCodeNotAllowed = code.DefaultRegistry.Register(code.ScopeSalesInvoice, code.CatInput, 4,
+		"tax classification code is not on the jurisdiction's allow-list")
+
+	ErrDuplicateTaxCode = code.DefaultRegistry.Register(code.ScopeSalesInvoice, code.CatInput, 5,
+		"special procedure code repeated on the same item")
+
+	ErrExclusiveTaxCodes = code.DefaultRegistry.Register(code.ScopeSalesInvoice, code.CatInput, 6,
+		"mutually exclusive special procedure codes on the same item")
+)
+
+// TaxCodeValidator enforces jurisdictional tax classification codes
+// (GTUCode, SpecialProcedure) against a per-company/jurisdiction
+// allow-list.
+type TaxCodeValidator struct {
+	Finance salesinvoice.FinanceLookup
+	// ExclusivePairs declares special-procedure codes that cannot appear
+	// together on the same item. It only needs one direction per pair
+	// (e.g. {"SP1": "SP2"}); Validate checks both directions.
+	ExclusivePairs map[string]string
+}
+
+// NewTaxCodeValidator wires a FinanceLookup and the mutually-exclusive
+// special-procedure pairs into a TaxCodeValidator.
+func NewTaxCodeValidator(finance salesinvoice.FinanceLookup, exclusivePairs map[string]string) *TaxCodeValidator {
+	return &TaxCodeValidator{Finance: finance, ExclusivePairs: exclusivePairs}
+}
+
+// Validate checks every item's GTUCode and SpecialProcedure tags against
+// the allow-list for s.Company/jurisdiction.
+func (v *TaxCodeValidator) Validate(s *salesinvoice.SalesInvoice, jurisdiction string) error {
+	gtuAllowed, spAllowed, err := v.Finance.GetAllowedTaxCodes(s.Company, jurisdiction)
+	if err != nil {
+		return err
+	}
+	gtuSet := toSet(gtuAllowed)
+	spSet := toSet(spAllowed)
+
+	for idx, item := range s.Items {
+		if item.GTUCode != "" && !gtuSet[item.GTUCode] {
+			return NewValidationError(ErrTaxCodeNotAllowed,
+				"item_index", idx, "gtu_code", item.GTUCode)
+		}
+
+		seen := make(map[string]bool, len(item.SpecialProcedure))
+		for _, sp := range item.SpecialProcedure {
+			if !spSet[sp] {
+				return NewValidationError(ErrTaxCodeNotAllowed,
+					"item_index", idx, "special_procedure", sp)
+			}
+			if seen[sp] {
+				return NewValidationError(ErrDuplicateTaxCode,
+					"item_index", idx, "special_procedure", sp)
+			}
+			seen[sp] = true
+		}
+
+		for _, sp := range item.SpecialProcedure {
+			conflict, ok := v.ExclusivePairs[sp]
+			if !ok {
+				continue
+			}
+			if seen[conflict] {
+				return NewValidationError(ErrExclusiveTaxCodes,
+					"item_index", idx, "special_procedure", sp, "conflicts_with", conflict)
+			}
+		}
+	}
+	return nil
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
@@ -0,0 +1,81 @@
+package validation
+
+import (
+	"errors"
+	"testing"
+
+	salesinvoice "github.com/Aagambot/AI-modernization-tool/sales_invoice"
+)
+
+func TestTaxCodeValidator(t *testing.T) {
+	finance := &MockFinanceLookup{
+		GTUCodes: []string{"GTU_01", "GTU_02"},
+		SPCodes:  []string{"SP1", "SP2", "SP3"},
+	}
+	exclusive := map[string]string{"SP1": "SP2"}
+
+	tests := []struct {
+		name    string
+		items   []salesinvoice.SalesInvoiceItem
+		wantErr error
+	}{
+		{
+			name:    "allowed GTU and SP codes",
+			items:   []salesinvoice.SalesInvoiceItem{{GTUCode: "GTU_01", SpecialProcedure: []string{"SP3"}}},
+			wantErr: nil,
+		},
+		{
+			name:    "GTU code not on allow-list",
+			items:   []salesinvoice.SalesInvoiceItem{{GTUCode: "GTU_99"}},
+			wantErr: ErrTaxCodeNotAllowed,
+		},
+		{
+			name:    "special procedure not on allow-list",
+			items:   []salesinvoice.SalesInvoiceItem{{SpecialProcedure: []string{"SP9"}}},
+			wantErr: ErrTaxCodeNotAllowed,
+		},
+		{
+			name:    "duplicate special procedure on one item",
+			items:   []salesinvoice.SalesInvoiceItem{{SpecialProcedure: []string{"SP3", "SP3"}}},
+			wantErr: ErrDuplicateTaxCode,
+		},
+		{
+			name:    "mutually exclusive special procedures",
+			items:   []salesinvoice.SalesInvoiceItem{{SpecialProcedure: []string{"SP1", "SP2"}}},
+			wantErr: ErrExclusiveTaxCodes,
+		},
+		{
+			name:    "mutually exclusive special procedures, declared in reverse order",
+			items:   []salesinvoice.SalesInvoiceItem{{SpecialProcedure: []string{"SP2", "SP1"}}},
+			wantErr: ErrExclusiveTaxCodes,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewTaxCodeValidator(finance, exclusive)
+			inv := &salesinvoice.SalesInvoice{Name: "SINV-TAX", Company: "Acme PL", Items: tt.items}
+
+			err := v.Validate(inv, "PL")
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTaxCodeValidator_WiredIntoValidate(t *testing.T) {
+	finance := &MockFinanceLookup{GTUCodes: []string{"GTU_01"}}
+	taxCodes := NewTaxCodeValidator(finance, nil)
+
+	invoice := salesinvoice.SalesInvoice{
+		Name:    "SINV-TAX-2",
+		Company: "Acme PL",
+		Items:   []salesinvoice.SalesInvoiceItem{{GTUCode: "GTU_99"}},
+	}
+
+	err := Validate(&invoice, &MockCreditChecker{}, &MockCustomerRegistry{}, taxCodes, "PL")
+	if !errors.Is(err, ErrTaxCodeNotAllowed) {
+		t.Errorf("Validate() error = %v, want ErrTaxCodeNotAllowed", err)
+	}
+}
@@ -0,0 +1,304 @@
+// Package validation holds the Sales Invoice business rules, split out of
+// the salesinvoice package so the domain model stays a plain data type and
+// the validation pipeline can grow (error codes, attributes, tax-code
+// rules) without bloating the domain package. Rules are package-level
+// functions rather than SalesInvoice methods because Go doesn't allow
+// methods on a type from another package.
+package validation
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"strings"
+
+	salesinvoice "github.com/Aagambot/AI-modernization-tool/sales_invoice"
+	"github.com/Aagambot/AI-modernization-tool/sales_invoice/code"
+)
+
+// --- 1. Sentinel Errors ---
+// These allow callers to handle specific business failures programmatically.
+// Each is registered with a stable code.Code so API callers can switch on
+// Code() instead of matching on the error string; errors.Is still works
+// because the sentinel value itself is what gets wrapped.
+
+var (
+	// ErrPOSAmountExceedsGrandTotal matches the logic in validate_pos.
+	ErrPOSAmountExceedsGrandTotal = code.DefaultRegistry.Register(code.ScopePOS, code.CatInput, 1,
+		"paid amount + write off amount cannot be greater than grand total")
+
+	// ErrCreditLimitExceeded matches the logic in check_credit_limit.
+	ErrCreditLimitExceeded = code.DefaultRegistry.Register(code.ScopeSalesInvoice, code.CatResource, 1,
+		"customer credit limit exceeded")
+
+	// Warehouse/Stock Errors identified via legacy extraction.
+	ErrDropShippingStockUpdate = code.DefaultRegistry.Register(code.ScopeSalesInvoice, code.CatInput, 2,
+		"stock cannot be updated for drop shipping items")
+
+	// Inter-company Errors identified via legacy extraction.
+	ErrInvalidInterCompanyEntity = code.DefaultRegistry.Register(code.ScopeInterCompany, code.CatAuth, 1,
+		"invalid entity for inter company transaction")
+
+	// Item Errors.
+	ErrInvalidQty = code.DefaultRegistry.Register(code.ScopeSalesInvoice, code.CatInput, 3,
+		"item quantity cannot be zero")
+)
+
+// --- 2. Validation Error Wrapper ---
+// Wraps sentinel errors with arbitrary key/value attributes, in the spirit
+// of the Algorand basics.serr pattern: a failure carries whatever context
+// diagnosed it, instead of callers having to re-plumb arguments just to
+// log them.
+
+type ValidationError struct {
+	Err   error
+	attrs map[string]any
+}
+
+// NewValidationError wraps err (normally one of the code.Error sentinels
+// above) and attaches the given key/value pairs, e.g.
+// NewValidationError(ErrInvalidQty, "invoice", s.Name, "item_index", i).
+// A non-string key, or a trailing key with no value, is dropped. If err is
+// itself a *ValidationError, its attributes are merged in and its own Err
+// is unwrapped to, so wrapping the same failure repeatedly still lets
+// errors.Is match the root sentinel.
+func NewValidationError(err error, kvs ...any) *ValidationError {
+	ve := &ValidationError{Err: err, attrs: map[string]any{}}
+	if inner, ok := err.(*ValidationError); ok {
+		ve.Err = inner.Err
+		for k, v := range inner.attrs {
+			ve.attrs[k] = v
+		}
+	}
+	for i := 0; i+1 < len(kvs); i += 2 {
+		if k, ok := kvs[i].(string); ok {
+			ve.attrs[k] = kvs[i+1]
+		}
+	}
+	return ve
+}
+
+// Attr attaches a single key/value attribute and returns e for chaining.
+func (e *ValidationError) Attr(k string, v any) *ValidationError {
+	if e.attrs == nil {
+		e.attrs = make(map[string]any)
+	}
+	e.attrs[k] = v
+	return e
+}
+
+// Attrs returns a copy of e's attached attributes.
+func (e *ValidationError) Attrs() map[string]any {
+	out := make(map[string]any, len(e.attrs))
+	for k, v := range e.attrs {
+		out[k] = v
+	}
+	return out
+}
+
+// Fields renders e's attributes as slog.Attr, sorted by key, for
+// structured logging.
+func (e *ValidationError) Fields() []slog.Attr {
+	keys := e.sortedAttrKeys()
+	fields := make([]slog.Attr, len(keys))
+	for i, k := range keys {
+		fields[i] = slog.Any(k, e.attrs[k])
+	}
+	return fields
+}
+
+func (e *ValidationError) sortedAttrKeys() []string {
+	keys := make([]string, 0, len(e.attrs))
+	for k := range e.attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Error renders "message [k1=v1 k2=v2 ...]" with keys in sorted order, so
+// the same attributes always render identically.
+func (e *ValidationError) Error() string {
+	if len(e.attrs) == 0 {
+		return e.Err.Error()
+	}
+	keys := e.sortedAttrKeys()
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = fmt.Sprintf("%s=%v", k, e.attrs[k])
+	}
+	return fmt.Sprintf("%s [%s]", e.Err.Error(), strings.Join(parts, " "))
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// coder is implemented by code.Error; ValidationError consults it to
+// expose Code/Scope/Category without caring how Err was constructed.
+type coder interface {
+	Code() code.Code
+}
+
+// Code returns the stable code.Code carried by Err, or 0 if Err wasn't
+// constructed from the code registry.
+func (e *ValidationError) Code() code.Code {
+	if c, ok := e.Err.(coder); ok {
+		return c.Code()
+	}
+	return 0
+}
+
+// Scope returns the code.Scope encoded in Code().
+func (e *ValidationError) Scope() code.Scope { return e.Code().Scope() }
+
+// Category returns the code.Category encoded in Code().
+func (e *ValidationError) Category() code.Category { return e.Code().Category() }
+
+// MarshalJSON renders e for HTTP handlers as
+// {code, scope, category, message, details}, where details carries the
+// attached attributes for programmatic consumption.
+func (e *ValidationError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Code     int            `json:"code"`
+		Scope    string         `json:"scope"`
+		Category string         `json:"category"`
+		Message  string         `json:"message"`
+		Details  map[string]any `json:"details,omitempty"`
+	}{
+		Code:     int(e.Code()),
+		Scope:    e.Scope().String(),
+		Category: e.Category().String(),
+		Message:  e.Err.Error(),
+		Details:  e.Attrs(),
+	})
+}
+
+// --- 3. Business Rules (The logic we extracted) ---
+
+// ValidatePOS checks if paid amount + write-off exceeds the total for returns.
+func ValidatePOS(s *salesinvoice.SalesInvoice) error {
+	if !s.IsReturn {
+		return nil
+	}
+
+	totalPaid := s.PaidAmount + s.WriteOffAmount
+	invoiceTotal := s.GrandTotal
+
+	// Handles rounding edge cases found in the coverage report.
+	if s.RoundedTotal > 0 {
+		invoiceTotal = s.RoundedTotal
+	}
+
+	// Calculate tolerance based on precision found in Python legacy.
+	tolerance := 1.0 / math.Pow(10, float64(s.Precision.GrandTotal+1))
+
+	if (totalPaid - invoiceTotal) > tolerance {
+		return NewValidationError(ErrPOSAmountExceedsGrandTotal,
+			"invoice", s.Name,
+			"customer", s.Customer,
+			"paid_amount", totalPaid,
+			"grand_total", invoiceTotal,
+			"tolerance", tolerance,
+		)
+	}
+	return nil
+}
+
+// CheckCreditLimit validates the customer's credit limit via the checker port.
+func CheckCreditLimit(s *salesinvoice.SalesInvoice, checker salesinvoice.CreditChecker) error {
+	// If bypass is true, we start with FALSE (do not validate).
+	// If bypass is false, we start with TRUE (must validate).
+	validateAgainstLimit := !s.BypassCreditLimitCheck
+
+	// Forced validation if items are not linked to previous docs.
+	for _, item := range s.Items {
+		if item.SalesOrder == "" && item.DeliveryNote == "" {
+			validateAgainstLimit = true
+			break
+		}
+	}
+
+	if validateAgainstLimit {
+		if err := checker.CheckLimit(s.Customer, s.Company, s.BypassCreditLimitCheck); err != nil {
+			return NewValidationError(err, "invoice", s.Name, "customer", s.Customer, "company", s.Company)
+		}
+	}
+	return nil
+}
+
+// ValidateDropShip ensures stock updates aren't triggered for supplier-delivered items.
+func ValidateDropShip(s *salesinvoice.SalesInvoice) error {
+	if !s.UpdateStock {
+		return nil
+	}
+	for i, item := range s.Items {
+		if item.DeliveredBySupplier {
+			return NewValidationError(ErrDropShippingStockUpdate,
+				"invoice", s.Name,
+				"item_index", i,
+				"item_code", item.ItemCode,
+			)
+		}
+	}
+	return nil
+}
+
+// ValidateInterCompany checks if internal customers are authorized for the company.
+func ValidateInterCompany(s *salesinvoice.SalesInvoice, registry salesinvoice.CustomerRegistry) error {
+	if s.InterCompanyRef == "" && s.IsInternalCustomer {
+		allowed, err := registry.GetAllowedCompanies(s.Customer)
+		if err != nil || !contains(allowed, s.Company) {
+			return NewValidationError(ErrInvalidInterCompanyEntity,
+				"invoice", s.Name,
+				"customer", s.Customer,
+				"company", s.Company,
+			)
+		}
+	}
+	return nil
+}
+
+// --- 4. Orchestrator (The Pipeline) ---
+// This matches the validate() method in ERPNext that calls all sub-validations.
+// Each failure is re-wrapped with the invoice name so the attribute is
+// present even on errors that bubble up from a sub-validation that didn't
+// set it itself; NewValidationError merges rather than shadows.
+//
+// taxCodes may be nil, in which case tax classification codes aren't
+// checked; jurisdiction is only consulted when taxCodes is non-nil.
+
+func Validate(s *salesinvoice.SalesInvoice, creditChecker salesinvoice.CreditChecker, registry salesinvoice.CustomerRegistry, taxCodes *TaxCodeValidator, jurisdiction string) error {
+	if err := ValidatePOS(s); err != nil {
+		return NewValidationError(err, "invoice", s.Name)
+	}
+	if err := CheckCreditLimit(s, creditChecker); err != nil {
+		return NewValidationError(err, "invoice", s.Name)
+	}
+	if err := ValidateDropShip(s); err != nil {
+		return NewValidationError(err, "invoice", s.Name)
+	}
+	if err := ValidateInterCompany(s, registry); err != nil {
+		return NewValidationError(err, "invoice", s.Name)
+	}
+	if taxCodes != nil {
+		if err := taxCodes.Validate(s, jurisdiction); err != nil {
+			return NewValidationError(err, "invoice", s.Name)
+		}
+	}
+	return nil
+}
+
+// --- 5. Helpers ---
+
+// contains resolves the "undefined: contains" compiler error.
+func contains(slice []string, item string) bool {
+	for _, s := range slice {
+		if s == item {
+			return true
+		}
+	}
+	return false
+}
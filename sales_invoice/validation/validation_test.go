@@ -0,0 +1,384 @@
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	salesinvoice "github.com/Aagambot/AI-modernization-tool/sales_invoice"
+	"github.com/Aagambot/AI-modernization-tool/sales_invoice/code"
+)
+
+// --- 1. Mock Implementations ---
+
+type MockCreditChecker struct {
+	shouldFail bool
+}
+
+func (m *MockCreditChecker) CheckLimit(customer string, company string, bypass bool) error {
+	if m.shouldFail {
+		return ErrCreditLimitExceeded
+	}
+	return nil
+}
+
+// MockCustomerRegistry satisfies the interface for advanced validation tests.
+type MockCustomerRegistry struct {
+	AllowedCompanies []string
+}
+
+func (m *MockCustomerRegistry) GetLoyaltyDetails(id string) (salesinvoice.LoyaltyInfo, error) {
+	return salesinvoice.LoyaltyInfo{}, nil
+}
+func (m *MockCustomerRegistry) ValidateCreditLimit(id, co string) error { return nil }
+func (m *MockCustomerRegistry) GetAddressDetails(id string) (salesinvoice.Address, error) {
+	return salesinvoice.Address{}, nil
+}
+func (m *MockCustomerRegistry) GetAllowedCompanies(id string) ([]string, error) {
+	return m.AllowedCompanies, nil
+}
+
+// MockFinanceLookup satisfies FinanceLookup for the orchestrator and
+// tax-code tests.
+type MockFinanceLookup struct {
+	GTUCodes []string
+	SPCodes  []string
+}
+
+func (m *MockFinanceLookup) GetExchangeRate(string, string) (float64, error) { return 1, nil }
+func (m *MockFinanceLookup) VerifyAccount(string, string) (bool, error)      { return true, nil }
+func (m *MockFinanceLookup) GetTaxTemplate(string) (salesinvoice.TaxTemplate, error) {
+	return salesinvoice.TaxTemplate{}, nil
+}
+func (m *MockFinanceLookup) GetAllowedTaxCodes(company, jurisdiction string) ([]string, []string, error) {
+	return m.GTUCodes, m.SPCodes, nil
+}
+
+// --- 2. Table-Driven Tests for POS Validation ---
+
+func TestValidatePOS(t *testing.T) {
+	tests := []struct {
+		name    string
+		invoice salesinvoice.SalesInvoice
+		wantErr error
+	}{
+		{
+			name: "Skip if not a return",
+			invoice: salesinvoice.SalesInvoice{
+				IsReturn:   false,
+				PaidAmount: 500,
+				GrandTotal: 100,
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Valid return within tolerance",
+			invoice: salesinvoice.SalesInvoice{
+				IsReturn:   true,
+				PaidAmount: 100,
+				GrandTotal: 100,
+				Precision:  salesinvoice.PrecisionSettings{GrandTotal: 2},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Valid return with rounding", // Fixes Line 60 coverage
+			invoice: salesinvoice.SalesInvoice{
+				IsReturn:     true,
+				PaidAmount:   100.50,
+				GrandTotal:   100.00,
+				RoundedTotal: 100.50,
+				Precision:    salesinvoice.PrecisionSettings{GrandTotal: 2},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Invalid return exceeding total",
+			invoice: salesinvoice.SalesInvoice{
+				IsReturn:   true,
+				PaidAmount: 150,
+				GrandTotal: 100,
+				Precision:  salesinvoice.PrecisionSettings{GrandTotal: 2},
+			},
+			wantErr: ErrPOSAmountExceedsGrandTotal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePOS(&tt.invoice)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ValidatePOS() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// --- 3. Table-Driven Tests for Credit Limit ---
+
+func TestCheckCreditLimit(t *testing.T) {
+	tests := []struct {
+		name     string
+		invoice  salesinvoice.SalesInvoice
+		mockFail bool
+		wantErr  error
+	}{
+		{
+			name: "Bypassed with linked items",
+			invoice: salesinvoice.SalesInvoice{
+				BypassCreditLimitCheck: true,
+				Items: []salesinvoice.SalesInvoiceItem{
+					{SalesOrder: "SO-001"},
+				},
+			},
+			mockFail: true,
+			wantErr:  nil,
+		},
+		{
+			name: "Force check due to unlinked items",
+			invoice: salesinvoice.SalesInvoice{
+				BypassCreditLimitCheck: true,
+				Items: []salesinvoice.SalesInvoiceItem{
+					{SalesOrder: ""},
+				},
+			},
+			mockFail: true,
+			wantErr:  ErrCreditLimitExceeded,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &MockCreditChecker{shouldFail: tt.mockFail}
+			err := CheckCreditLimit(&tt.invoice, mock)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("CheckCreditLimit() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// --- 4. Advanced Validation Tests (DropShip & InterCompany) ---
+
+func TestAdvancedValidations(t *testing.T) {
+	tests := []struct {
+		name    string
+		invoice salesinvoice.SalesInvoice
+		wantErr error
+	}{
+		{
+			name: "Fail on Drop Ship with Update Stock",
+			invoice: salesinvoice.SalesInvoice{
+				UpdateStock: true,
+				Items: []salesinvoice.SalesInvoiceItem{
+					{DeliveredBySupplier: true},
+				},
+			},
+			wantErr: ErrDropShippingStockUpdate,
+		},
+		{
+			name: "Pass on Drop Ship when Update Stock disabled", // Coverage for early return
+			invoice: salesinvoice.SalesInvoice{
+				UpdateStock: false,
+				Items: []salesinvoice.SalesInvoiceItem{
+					{DeliveredBySupplier: true},
+				},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "Fail on Internal Customer not in Allowed List",
+			invoice: salesinvoice.SalesInvoice{
+				IsInternalCustomer: true,
+				Customer:           "Internal-001",
+				Company:            "Unauthorized-Corp",
+			},
+			wantErr: ErrInvalidInterCompanyEntity,
+		},
+		{
+			name: "Pass on Internal Customer in Allowed List", // Coverage for 'contains' success
+			invoice: salesinvoice.SalesInvoice{
+				IsInternalCustomer: true,
+				Customer:           "Internal-001",
+				Company:            "Authorized-Corp",
+			},
+			wantErr: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRegistry := &MockCustomerRegistry{
+				AllowedCompanies: []string{"Authorized-Corp"},
+			}
+
+			// Test DropShip
+			err := ValidateDropShip(&tt.invoice)
+			if tt.wantErr == ErrDropShippingStockUpdate {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("ValidateDropShip() expected error %v, got %v", tt.wantErr, err)
+				}
+			}
+
+			// Test InterCompany
+			err = ValidateInterCompany(&tt.invoice, mockRegistry)
+			if tt.wantErr == ErrInvalidInterCompanyEntity || tt.wantErr == nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("ValidateInterCompany() expected error %v, got %v", tt.wantErr, err)
+				}
+			}
+		})
+	}
+}
+
+// --- 5. Pipeline & Formatting Tests ---
+
+func TestFullPipeline(t *testing.T) {
+	mockChecker := &MockCreditChecker{}
+	mockRegistry := &MockCustomerRegistry{AllowedCompanies: []string{"Authorized-Corp"}}
+
+	invoice := salesinvoice.SalesInvoice{
+		IsReturn:   true,
+		GrandTotal: 100,
+		PaidAmount: 50,
+		Company:    "Authorized-Corp",
+		Precision:  salesinvoice.PrecisionSettings{GrandTotal: 2},
+	}
+
+	// Triggers coverage for the Orchestrator Validate() function
+	err := Validate(&invoice, mockChecker, mockRegistry, nil, "")
+	if err != nil {
+		t.Errorf("Validate() unexpectedly failed: %v", err)
+	}
+}
+
+func TestErrorFormatting(t *testing.T) {
+	// Triggers coverage for ValidationError.Error() branches
+	tests := []struct {
+		name string
+		vErr *ValidationError
+	}{
+		{
+			name: "With Attrs",
+			vErr: NewValidationError(ErrInvalidQty, "item_code", "WIDGET", "qty", -5),
+		},
+		{
+			name: "Without Attrs",
+			vErr: NewValidationError(ErrInvalidQty),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := tt.vErr.Error()
+			if msg == "" {
+				t.Error("Error() returned empty string")
+			}
+		})
+	}
+}
+
+// --- 6. Error Code Tests ---
+
+func TestValidationError_Code(t *testing.T) {
+	vErr := NewValidationError(ErrPOSAmountExceedsGrandTotal, "paid_amount", 150.0, "grand_total", 100.0)
+
+	if vErr.Scope() != code.ScopePOS {
+		t.Errorf("Scope() = %v, want %v", vErr.Scope(), code.ScopePOS)
+	}
+	if vErr.Category() != code.CatInput {
+		t.Errorf("Category() = %v, want %v", vErr.Category(), code.CatInput)
+	}
+	if vErr.Code() == 0 {
+		t.Error("Code() returned 0 for a registry-backed sentinel")
+	}
+	if !errors.Is(vErr, ErrPOSAmountExceedsGrandTotal) {
+		t.Error("errors.Is() should still match the wrapped sentinel")
+	}
+}
+
+func TestValidationError_MarshalJSON(t *testing.T) {
+	vErr := NewValidationError(ErrInvalidQty, "item_index", 0, "item_code", "WIDGET")
+
+	data, err := json.Marshal(vErr)
+	if err != nil {
+		t.Fatalf("MarshalJSON() error = %v", err)
+	}
+
+	var decoded struct {
+		Code     int            `json:"code"`
+		Scope    string         `json:"scope"`
+		Category string         `json:"category"`
+		Message  string         `json:"message"`
+		Details  map[string]any `json:"details"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	if decoded.Code != int(vErr.Code()) {
+		t.Errorf("decoded code = %d, want %d", decoded.Code, vErr.Code())
+	}
+	if decoded.Scope != "SalesInvoice" {
+		t.Errorf("decoded scope = %q, want %q", decoded.Scope, "SalesInvoice")
+	}
+	if decoded.Details["item_code"] != "WIDGET" {
+		t.Errorf("decoded details[item_code] = %v, want WIDGET", decoded.Details["item_code"])
+	}
+}
+
+// --- 7. Attribute Propagation Tests ---
+
+func TestValidationError_AttrsAndFormatting(t *testing.T) {
+	vErr := NewValidationError(ErrInvalidQty, "item_code", "WIDGET", "item_index", 2)
+
+	want := "item quantity cannot be zero [item_code=WIDGET item_index=2]"
+	if got := vErr.Error(); got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+
+	attrs := vErr.Attrs()
+	if attrs["item_code"] != "WIDGET" || attrs["item_index"] != 2 {
+		t.Errorf("Attrs() = %v, missing expected keys", attrs)
+	}
+
+	fields := vErr.Fields()
+	if len(fields) != 2 || fields[0].Key != "item_code" {
+		t.Errorf("Fields() = %v, want sorted [item_code item_index]", fields)
+	}
+}
+
+func TestValidationError_MergesAttrsOnWrap(t *testing.T) {
+	inner := NewValidationError(ErrInvalidQty, "item_code", "WIDGET")
+	outer := NewValidationError(inner, "invoice", "SINV-0001")
+
+	if !errors.Is(outer, ErrInvalidQty) {
+		t.Error("wrapping a *ValidationError should still unwrap to the root sentinel")
+	}
+	attrs := outer.Attrs()
+	if attrs["item_code"] != "WIDGET" || attrs["invoice"] != "SINV-0001" {
+		t.Errorf("Attrs() = %v, want both inner and outer attributes merged", attrs)
+	}
+}
+
+func TestValidate_PropagatesInvoiceAttr(t *testing.T) {
+	invoice := salesinvoice.SalesInvoice{
+		Name:       "SINV-0042",
+		IsReturn:   true,
+		GrandTotal: 100,
+		PaidAmount: 500,
+		Precision:  salesinvoice.PrecisionSettings{GrandTotal: 2},
+	}
+
+	err := Validate(&invoice, &MockCreditChecker{}, &MockCustomerRegistry{}, nil, "")
+	vErr, ok := err.(*ValidationError)
+	if !ok {
+		t.Fatalf("Validate() error type = %T, want *ValidationError", err)
+	}
+	if vErr.Attrs()["invoice"] != "SINV-0042" {
+		t.Errorf("Attrs()[\"invoice\"] = %v, want SINV-0042", vErr.Attrs()["invoice"])
+	}
+	if vErr.Attrs()["customer"] == nil {
+		t.Error("expected the customer attribute from ValidatePOS to survive wrapping")
+	}
+}